@@ -1,42 +1,113 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/logrusorgru/aurora"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 
 	"replicate.ai/cli/pkg/console"
 	"replicate.ai/cli/pkg/param"
+	paramdiff "replicate.ai/cli/pkg/param/diff"
 	"replicate.ai/cli/pkg/project"
+	"replicate.ai/cli/pkg/query"
 )
 
+// diffFormats are the valid values for --format.
+var diffFormats = []string{"text", "json", "yaml", "ndjson"}
+
 func newDiffCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "diff <ID> <ID>",
-		Short: "Compare two experiments or checkpoints",
-		Long: `Compare two experiments or checkpoints.
+		Use:   "diff <ID> <ID> [<ID>...]",
+		Short: "Compare two or more experiments or checkpoints",
+		Long: `Compare two or more experiments or checkpoints.
 
 If an experiment ID is passed, it will pick the best checkpoint from that experiment. If a primary metric is not defined in replicate.yaml, it will use the latest checkpoint.`,
 		RunE: diffCheckpoints,
-		Args: cobra.ExactArgs(2),
+		Args: cobra.MinimumNArgs(2),
 	}
 
-	// TODO(andreas): support json output
 	addStorageURLFlag(cmd)
+	cmd.Flags().String("project", "", "Only show these params/metrics/labels, e.g. --project 'params.learning_rate,metrics.loss,labels.dataset'")
+	cmd.Flags().String("filter", "", "Only compare runs matching this expression, e.g. --filter 'metrics.loss<0.1 AND params.optimizer=adam'")
+	cmd.Flags().String("format", "text", fmt.Sprintf("Output format, one of: %s. The json/yaml/ndjson schema differs for exactly two runs left after --filter (left/right/params/metrics) vs. any other number or --project (columns/rows); every document carries a \"schema\" field (\"pair\" or \"table\") so scripts can branch on that instead of arg count", strings.Join(diffFormats, ", ")))
+	cmd.Flags().Float64("tolerance", 1e-6, "Maximum absolute difference between two numbers for them to be considered equal. Only the two-run text diff uses it; json/yaml/ndjson and the dense table compare values exactly")
+	cmd.Flags().String("select", "", "How to pick a checkpoint from each experiment: best (default), latest, first, a query like 'step=1000', or argmin(metrics.<k>)/argmax(metrics.<k>)")
+	cmd.Flags().String("left-select", "", "Override --select for the first ID, when comparing exactly two runs")
+	cmd.Flags().String("right-select", "", "Override --select for the second ID, when comparing exactly two runs")
 
 	return cmd
 }
 
 func diffCheckpoints(cmd *cobra.Command, args []string) error {
-	// TODO(andreas): generalize to >2 checkpoints/experiments
+	projectFlag, err := cmd.Flags().GetString("project")
+	if err != nil {
+		return err
+	}
+	filterFlag, err := cmd.Flags().GetString("filter")
+	if err != nil {
+		return err
+	}
+	formatFlag, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if !contains(diffFormats, formatFlag) {
+		return fmt.Errorf("--format must be one of: %s", strings.Join(diffFormats, ", "))
+	}
+	tolerance, err := cmd.Flags().GetFloat64("tolerance")
+	if err != nil {
+		return err
+	}
+	selectFlag, err := cmd.Flags().GetString("select")
+	if err != nil {
+		return err
+	}
+	leftSelectFlag, err := cmd.Flags().GetString("left-select")
+	if err != nil {
+		return err
+	}
+	rightSelectFlag, err := cmd.Flags().GetString("right-select")
+	if err != nil {
+		return err
+	}
+	if (leftSelectFlag != "" || rightSelectFlag != "") && len(args) != 2 {
+		return fmt.Errorf("--left-select/--right-select only apply when comparing exactly two IDs, use --select instead")
+	}
 
-	prefix1 := args[0]
-	prefix2 := args[1]
+	selectors := make([]project.CheckpointSelector, len(args))
+	if selectFlag != "" {
+		sel, err := parseSelector(selectFlag)
+		if err != nil {
+			return err
+		}
+		for i := range selectors {
+			selectors[i] = sel
+		}
+	}
+	if leftSelectFlag != "" {
+		sel, err := parseSelector(leftSelectFlag)
+		if err != nil {
+			return err
+		}
+		selectors[0] = sel
+	}
+	if rightSelectFlag != "" {
+		sel, err := parseSelector(rightSelectFlag)
+		if err != nil {
+			return err
+		}
+		selectors[1] = sel
+	}
 
 	storageURL, sourceDir, err := getStorageURLFromFlagOrConfig(cmd)
 	if err != nil {
@@ -48,87 +119,595 @@ func diffCheckpoints(cmd *cobra.Command, args []string) error {
 	}
 	proj := project.NewProject(store)
 	au := getAurora()
-	return printDiff(os.Stdout, au, proj, prefix1, prefix2)
+	return printDiff(os.Stdout, au, proj, args, selectors, projectFlag, filterFlag, formatFlag, tolerance)
+}
+
+var (
+	atStepSelectorRegexp  = regexp.MustCompile(`^step\s*=\s*(-?\d+)$`)
+	atEpochSelectorRegexp = regexp.MustCompile(`^epoch\s*=\s*(-?\d+)$`)
+)
+
+// parseSelector parses a --select/--left-select/--right-select flag value
+// into a project.CheckpointSelector. An empty expr means "use the default",
+// represented as a nil selector.
+func parseSelector(expr string) (project.CheckpointSelector, error) {
+	expr = strings.TrimSpace(expr)
+	switch expr {
+	case "":
+		return nil, nil
+	case "best":
+		return &project.BestSelector{}, nil
+	case "latest":
+		return &project.LatestSelector{}, nil
+	case "first":
+		return &project.FirstSelector{}, nil
+	}
+	if m := atStepSelectorRegexp.FindStringSubmatch(expr); m != nil {
+		step, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, err
+		}
+		return &project.AtStepSelector{Step: step}, nil
+	}
+	if m := atEpochSelectorRegexp.FindStringSubmatch(expr); m != nil {
+		epoch, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, err
+		}
+		return &project.AtEpochSelector{Epoch: epoch}, nil
+	}
+	return &project.QuerySelector{Expr: expr}, nil
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// run is a single checkpoint/experiment being compared, flattened into
+// "namespace.key" values (e.g. "params.learning_rate", "metrics.loss",
+// "labels.dataset") so it can be filtered and projected with the query
+// package.
+type run struct {
+	checkpoint *project.Checkpoint
+	experiment *project.Experiment
+	values     map[string]string
 }
 
-// TODO: implement this as a thing in console
-func br(w *tabwriter.Writer) {
-	fmt.Fprintf(w, "\t\t\n")
+func (r *run) Lookup(field string) (string, bool) {
+	v, ok := r.values[field]
+	return v, ok
 }
 
-func heading(w *tabwriter.Writer, au aurora.Aurora, text string) {
-	fmt.Fprintf(w, "%s\t\t\n", au.Bold(text))
+func (r *run) label() string {
+	return fmt.Sprintf("%s (%s)", r.checkpoint.ShortID(), r.experiment.ShortID())
 }
 
-// TODO(andreas): diff command line arguments
-func printDiff(out io.Writer, au aurora.Aurora, proj *project.Project, prefix1 string, prefix2 string) error {
-	com1, err := loadCheckpoint(proj, prefix1)
+// loadRun resolves prefix to a checkpoint, using selector to pick among an
+// experiment's checkpoints if prefix names an experiment and selector is
+// non-nil. If selector is nil, it falls back to loadCheckpoint's default
+// best-or-latest behaviour.
+func loadRun(proj *project.Project, prefix string, selector project.CheckpointSelector) (*run, error) {
+	checkpoint, err := resolveCheckpoint(proj, prefix, selector)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	com2, err := loadCheckpoint(proj, prefix2)
+	experiment, err := proj.ExperimentByID(checkpoint.ExperimentID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	values := map[string]string{}
+	for k, v := range experiment.Params {
+		values["params."+k] = v.String()
+	}
+	for k, v := range checkpoint.Metrics {
+		values["metrics."+k] = v.String()
+	}
+	for k, v := range experiment.Labels {
+		values["labels."+k] = v.String()
+	}
+	return &run{checkpoint: checkpoint, experiment: experiment, values: values}, nil
+}
+
+// printDiff compares the checkpoints/experiments matching prefixes, printing
+// a two-column diff when exactly two runs remain after --filter and no
+// projection is given (for backwards compatibility), or a dense table when
+// any other number of runs remains or a projection is given.
+//
+// Structured output (--format json/yaml/ndjson) has two distinct schemas
+// depending on arity: exactly two runs with no --project produces the
+// pairDiff schema ({schema: "pair", left, right, params, metrics,
+// experiments} -- see computePairDiff), while anything else (N-way, or 2-way
+// with --project) produces the {schema: "table", columns, rows} tableRow
+// schema from renderTableStructured. Both payloads (and, for ndjson, every
+// line within them) carry a "schema" field so a scripted consumer can branch
+// on the document's shape instead of on how many IDs it happened to pass.
+// An empty --filter result still emits a well-formed, empty document of the
+// "table" schema (no lines at all for ndjson), whatever the arguments, so
+// piping into jq or a spreadsheet never sees the human-readable
+// "(no runs matched --filter)" text unless --format text was requested.
+func printDiff(out io.Writer, au aurora.Aurora, proj *project.Project, prefixes []string, selectors []project.CheckpointSelector, projectExpr, filterExpr, format string, tolerance float64) error {
+	runs := make([]*run, len(prefixes))
+	for i, prefix := range prefixes {
+		r, err := loadRun(proj, prefix, selectors[i])
+		if err != nil {
+			return err
+		}
+		runs[i] = r
+	}
+
+	if filterExpr != "" {
+		expr, err := query.Parse(filterExpr)
+		if err != nil {
+			return fmt.Errorf("invalid --filter: %w", err)
+		}
+		filtered := runs[:0]
+		for _, r := range runs {
+			ok, err := expr.Eval(r)
+			if err != nil {
+				return fmt.Errorf("invalid --filter: %w", err)
+			}
+			if ok {
+				filtered = append(filtered, r)
+			}
+		}
+		runs = filtered
+	}
+
+	if len(runs) == 0 {
+		if format != "text" {
+			columns := parseProjection(projectExpr)
+			if columns == nil {
+				columns = []string{}
+			}
+			return renderTableStructured(out, runs, columns, format)
+		}
+		fmt.Fprintln(out, au.Faint("(no runs matched --filter)"))
+		return nil
+	}
+
+	if len(runs) == 2 && projectExpr == "" {
+		return renderPairDiff(out, au, runs[0], runs[1], format, tolerance)
+	}
+
+	columns := parseProjection(projectExpr)
+	if columns == nil {
+		columns = unionColumns(runs)
+	}
+	if format != "text" {
+		return renderTableStructured(out, runs, columns, format)
 	}
-	exp1, err := proj.ExperimentByID(com1.ExperimentID)
+	return printDenseTable(out, au, runs, columns)
+}
+
+// fieldDiff is one key of a pairDiff's Params or Metrics map.
+type fieldDiff struct {
+	Left     string   `json:"left" yaml:"left"`
+	Right    string   `json:"right" yaml:"right"`
+	Changed  bool     `json:"changed" yaml:"changed"`
+	Delta    *float64 `json:"delta,omitempty" yaml:"delta,omitempty"`
+	PctDelta *float64 `json:"pct_delta,omitempty" yaml:"pct_delta,omitempty"`
+}
+
+type experimentSummary struct {
+	ID           string `json:"id" yaml:"id"`
+	CheckpointID string `json:"checkpoint_id" yaml:"checkpoint_id"`
+}
+
+// pairDiffSchema/tableSchema are the values of the "schema" discriminator
+// field in printDiff's two structured-output shapes, so a scripted consumer
+// can branch on the payload rather than on how many IDs it passed.
+const (
+	pairDiffSchema = "pair"
+	tableSchema    = "table"
+)
+
+// pairDiff is the stable schema for `replicate diff --format json|yaml` when
+// comparing exactly two checkpoints/experiments.
+type pairDiff struct {
+	Schema      string               `json:"schema" yaml:"schema"`
+	Left        string               `json:"left" yaml:"left"`
+	Right       string               `json:"right" yaml:"right"`
+	Params      map[string]fieldDiff `json:"params" yaml:"params"`
+	Metrics     map[string]fieldDiff `json:"metrics" yaml:"metrics"`
+	Experiments struct {
+		Left  experimentSummary `json:"left" yaml:"left"`
+		Right experimentSummary `json:"right" yaml:"right"`
+	} `json:"experiments" yaml:"experiments"`
+}
+
+// computePairDiff builds the diff model for r1 vs r2, with no rendering
+// concerns -- this is shared by the json, yaml and ndjson renderers. The
+// text renderer does not use it; it diffs the param values structurally.
+func computePairDiff(r1, r2 *run) *pairDiff {
+	model := &pairDiff{
+		Schema:  pairDiffSchema,
+		Left:    r1.checkpoint.ShortID(),
+		Right:   r2.checkpoint.ShortID(),
+		Params:  diffFields(paramMapToStringMap(r1.experiment.Params), paramMapToStringMap(r2.experiment.Params), false),
+		Metrics: diffFields(paramMapToStringMap(r1.checkpoint.Metrics), paramMapToStringMap(r2.checkpoint.Metrics), true),
+	}
+	model.Experiments.Left = experimentSummary{ID: r1.experiment.ShortID(), CheckpointID: r1.checkpoint.ShortID()}
+	model.Experiments.Right = experimentSummary{ID: r2.experiment.ShortID(), CheckpointID: r2.checkpoint.ShortID()}
+	return model
+}
+
+// diffFields merges left and right into a map keyed by field name. Numeric
+// deltas are only computed for metrics (withDelta), per the stable schema:
+// params are {left,right,changed}, metrics are {left,right,changed,delta,pct_delta}.
+func diffFields(left, right map[string]string, withDelta bool) map[string]fieldDiff {
+	result := map[string]fieldDiff{}
+	for k, v := range mapString(left, right) {
+		fd := fieldDiff{Changed: true}
+		if v[0] != nil {
+			fd.Left = *v[0]
+		}
+		if v[1] != nil {
+			fd.Right = *v[1]
+		}
+		if withDelta && v[0] != nil && v[1] != nil {
+			if lf, rf, ok := bothFloats(fd.Left, fd.Right); ok {
+				delta := rf - lf
+				fd.Delta = &delta
+				if lf != 0 {
+					pct := delta / lf * 100
+					fd.PctDelta = &pct
+				}
+			}
+		}
+		result[k] = fd
+	}
+	// keys with no diff still belong in the schema, just marked unchanged
+	for k, v := range left {
+		if _, ok := result[k]; !ok {
+			result[k] = fieldDiff{Left: v, Right: v, Changed: false}
+		}
+	}
+	return result
+}
+
+func bothFloats(a, b string) (float64, float64, bool) {
+	af, err := strconv.ParseFloat(a, 64)
 	if err != nil {
-		return err
+		return 0, 0, false
 	}
-	exp2, err := proj.ExperimentByID(com2.ExperimentID)
+	bf, err := strconv.ParseFloat(b, 64)
 	if err != nil {
+		return 0, 0, false
+	}
+	return af, bf, true
+}
+
+// renderPairDiff writes the diff between r1 and r2 in the requested format.
+func renderPairDiff(out io.Writer, au aurora.Aurora, r1, r2 *run, format string, tolerance float64) error {
+	if format == "text" {
+		return renderPairDiffText(out, au, r1, r2, tolerance)
+	}
+
+	model := computePairDiff(r1, r2)
+	switch format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(model)
+	case "yaml":
+		b, err := yaml.Marshal(model)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(b)
 		return err
+	case "ndjson":
+		return renderPairDiffNDJSON(out, model)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// renderPairDiffNDJSON emits one JSON object per param/metric row, so it can
+// be piped into jq or loaded into a spreadsheet.
+func renderPairDiffNDJSON(out io.Writer, model *pairDiff) error {
+	enc := json.NewEncoder(out)
+	type row struct {
+		Schema string `json:"schema"`
+		Kind   string `json:"kind"`
+		Key    string `json:"key"`
+		fieldDiff
+	}
+	for _, section := range []struct {
+		kind   string
+		fields map[string]fieldDiff
+	}{
+		{"param", model.Params},
+		{"metric", model.Metrics},
+	} {
+		keys := make([]string, 0, len(section.fields))
+		for k := range section.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := enc.Encode(row{Schema: pairDiffSchema, Kind: section.kind, Key: k, fieldDiff: section.fields[k]}); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
 
-	// min width for 3 columns in 78 char terminal
+// renderPairDiffText is the original two-column, key-per-row diff, except
+// each row is itself a recursive structural diff (see pkg/param/diff) so
+// that nested params (lists, dicts) only show the leaves that changed.
+func renderPairDiffText(out io.Writer, au aurora.Aurora, r1, r2 *run, tolerance float64) error {
 	w := tabwriter.NewWriter(out, 78/3, 8, 2, ' ', 0)
 
-	fmt.Fprintf(w, "Checkpoint:\t%s\t%s\n", com1.ShortID(), com2.ShortID())
-	fmt.Fprintf(w, "Experiment:\t%s\t%s\n", com1.ShortExperimentID(), com2.ShortExperimentID())
+	fmt.Fprintf(w, "Checkpoint:\t%s\t%s\n", r1.checkpoint.ShortID(), r2.checkpoint.ShortID())
+	fmt.Fprintf(w, "Experiment:\t%s\t%s\n", r1.experiment.ShortID(), r2.experiment.ShortID())
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	opts := paramdiff.Options{Tolerance: tolerance}
 
-	br(w)
-	heading(w, au, "Params")
-	printMapDiff(w, au, paramMapToStringMap(exp1.Params), paramMapToStringMap(exp2.Params))
-	br(w)
+	fmt.Fprintln(out)
+	heading(out, au, "Params")
+	printStructuralDiff(out, au, r1.experiment.Params, r2.experiment.Params, opts)
 
-	heading(w, au, "Metrics")
+	fmt.Fprintln(out)
+	heading(out, au, "Metrics")
 	// TODO(bfirsh): put primary metric first
-	printMapDiff(w, au, paramMapToStringMap(com1.Metrics), paramMapToStringMap(com2.Metrics))
-	br(w)
+	printStructuralDiff(out, au, r1.checkpoint.Metrics, r2.checkpoint.Metrics, opts)
 
-	return w.Flush()
+	return nil
 }
 
-func printMapDiff(w *tabwriter.Writer, au aurora.Aurora, map1, map2 map[string]string) {
-	diffMap := mapString(map1, map2)
+func heading(out io.Writer, au aurora.Aurora, text string) {
+	fmt.Fprintln(out, au.Bold(text))
+}
 
-	// sort the keys
-	type keyVal struct {
-		key   string
-		value []*string
+// printStructuralDiff prints, for each key present in left or right, the
+// param/diff.Diff lines between left[k] and right[k], indented and
+// colorized by gutter.
+func printStructuralDiff(out io.Writer, au aurora.Aurora, left, right map[string]*param.Value, opts paramdiff.Options) {
+	keys := map[string]bool{}
+	for k := range left {
+		keys[k] = true
 	}
-	keyVals := []keyVal{}
-	for k, v := range diffMap {
-		keyVals = append(keyVals, keyVal{k, v})
+	for k := range right {
+		keys[k] = true
 	}
-	sort.Slice(keyVals, func(i, j int) bool {
-		return keyVals[i].key < keyVals[j].key
-	})
+	sortedKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	any := false
+	for _, k := range sortedKeys {
+		lines := paramdiff.Diff(left[k], right[k], opts)
+		changed := false
+		for _, l := range lines {
+			if l.Op != paramdiff.Same {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			continue
+		}
+		any = true
+		fmt.Fprintf(out, "  %s:\n", k)
+		for _, l := range lines {
+			printDiffLine(out, au, l)
+		}
+	}
+	if !any {
+		fmt.Fprintln(out, au.Faint("  (no difference)"))
+	}
+}
+
+func printDiffLine(out io.Writer, au aurora.Aurora, l paramdiff.DiffLine) {
+	indent := strings.Repeat("  ", l.Indent+1)
+	switch l.Op {
+	case paramdiff.Add:
+		fmt.Fprintf(out, "%s%s %s\n", indent, au.Green("+"), l.Text)
+	case paramdiff.Del:
+		fmt.Fprintf(out, "%s%s %s\n", indent, au.Red("-"), l.Text)
+	default:
+		fmt.Fprintf(out, "%s  %s\n", indent, l.Text)
+	}
+}
+
+// tableRow is the structured form of one run in an N-way --format json/yaml/ndjson table.
+// This is a different schema from pairDiff -- see the note on printDiff.
+type tableRow struct {
+	Schema string            `json:"schema" yaml:"schema"`
+	Run    string            `json:"run" yaml:"run"`
+	Values map[string]string `json:"values" yaml:"values"`
+}
+
+func renderTableStructured(out io.Writer, runs []*run, columns []string, format string) error {
+	rows := make([]tableRow, len(runs))
+	for i, r := range runs {
+		values := map[string]string{}
+		for _, col := range columns {
+			if v, ok := r.values[col]; ok {
+				values[col] = v
+			}
+		}
+		rows[i] = tableRow{Schema: tableSchema, Run: r.label(), Values: values}
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Schema  string     `json:"schema"`
+			Columns []string   `json:"columns"`
+			Rows    []tableRow `json:"rows"`
+		}{tableSchema, columns, rows})
+	case "yaml":
+		b, err := yaml.Marshal(struct {
+			Schema  string     `yaml:"schema"`
+			Columns []string   `yaml:"columns"`
+			Rows    []tableRow `yaml:"rows"`
+		}{tableSchema, columns, rows})
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(b)
+		return err
+	case "ndjson":
+		enc := json.NewEncoder(out)
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// printDenseTable renders runs as rows and columns as, well, columns,
+// collapsing columns that are the same across every run and color-coding
+// the cells that differ from the first run.
+func printDenseTable(out io.Writer, au aurora.Aurora, runs []*run, columns []string) error {
+	varying, constant := collapseConstant(runs, columns)
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+
+	fmt.Fprint(w, "Run")
+	for _, col := range varying {
+		fmt.Fprintf(w, "\t%s", col)
+	}
+	fmt.Fprintln(w)
 
-	if len(keyVals) > 0 {
-		for _, kv := range keyVals {
-			left := "(not set)"
-			right := "(not set)"
-			if kv.value[0] != nil {
-				left = *(kv.value[0])
+	for _, r := range runs {
+		fmt.Fprint(w, r.label())
+		for _, col := range varying {
+			val, ok := r.values[col]
+			if !ok {
+				val = "(not set)"
+			}
+			ref, refOK := runs[0].values[col]
+			if !refOK {
+				ref = "(not set)"
+			}
+			if val != ref {
+				fmt.Fprintf(w, "\t%s", au.Red(val))
+			} else {
+				fmt.Fprintf(w, "\t%s", val)
 			}
-			if kv.value[1] != nil {
-				right = *(kv.value[1])
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(constant) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, au.Faint("Same for all runs:"))
+		keys := make([]string, 0, len(constant))
+		for k := range constant {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(out, "  %s: %s\n", k, constant[k])
+		}
+	}
+
+	return nil
+}
+
+// collapseConstant splits columns into those that vary across runs and
+// those that are set to the same value in every run. A column that is unset
+// in any run, including one unset in every run, counts as varying.
+func collapseConstant(runs []*run, columns []string) (varying []string, constant map[string]string) {
+	constant = map[string]string{}
+	for _, col := range columns {
+		first, ok := runs[0].values[col]
+		same := true
+		for _, r := range runs[1:] {
+			v, vOk := r.values[col]
+			if v != first || vOk != ok {
+				same = false
+				break
 			}
-			fmt.Fprintf(w, "%s:\t%s\t%s\n", kv.key, left, right)
 		}
-	} else {
-		fmt.Fprintf(w, "%s\t\t\n", au.Faint("(no difference)"))
+		if same && ok {
+			constant[col] = first
+		} else {
+			varying = append(varying, col)
+		}
+	}
+	return varying, constant
+}
+
+// parseProjection parses a --project flag value, a comma-separated list of
+// "namespace.key" columns (e.g. "params.learning_rate"), preserving declared
+// order. This dot-separated, "params"-namespaced form matches --filter's
+// grammar and run.values' keys; it's the deliberate column syntax for this
+// flag, not a loose example -- see the --project flag help for the canonical
+// reference, since it's the one place users should look. Returns nil if expr
+// is empty.
+func parseProjection(expr string) []string {
+	if strings.TrimSpace(expr) == "" {
+		return nil
+	}
+	parts := strings.Split(expr, ",")
+	columns := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			columns = append(columns, p)
+		}
+	}
+	return columns
+}
+
+// namespaceOrder fixes the column order for unionColumns: params, then
+// metrics, then labels.
+var namespaceOrder = map[string]int{"params": 0, "metrics": 1, "labels": 2}
+
+// unionColumns returns the sorted union of all "namespace.key" columns
+// across runs, ordered params, then metrics, then labels.
+func unionColumns(runs []*run) []string {
+	seen := map[string]bool{}
+	for _, r := range runs {
+		for k := range r.values {
+			seen[k] = true
+		}
+	}
+	columns := make([]string, 0, len(seen))
+	for k := range seen {
+		columns = append(columns, k)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		ni, ki := splitColumn(columns[i])
+		nj, kj := splitColumn(columns[j])
+		if ni != nj {
+			return namespaceOrder[ni] < namespaceOrder[nj]
+		}
+		return ki < kj
+	})
+	return columns
+}
+
+func splitColumn(column string) (namespace, key string) {
+	parts := strings.SplitN(column, ".", 2)
+	if len(parts) != 2 {
+		return "", column
 	}
+	return parts[0], parts[1]
 }
 
 func paramMapToStringMap(params map[string]*param.Value) map[string]string {
@@ -139,6 +718,30 @@ func paramMapToStringMap(params map[string]*param.Value) map[string]string {
 	return result
 }
 
+// resolveCheckpoint is like loadCheckpoint, except if prefix matches an
+// experiment and selector is non-nil, selector picks the checkpoint instead
+// of the default best-or-latest behaviour.
+func resolveCheckpoint(proj *project.Project, prefix string, selector project.CheckpointSelector) (*project.Checkpoint, error) {
+	if selector == nil {
+		return loadCheckpoint(proj, prefix)
+	}
+	obj, err := proj.CheckpointOrExperimentFromPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if obj.Checkpoint != nil {
+		return obj.Checkpoint, nil
+	}
+	checkpoint, err := selector.SelectCheckpoint(proj, obj.Experiment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint == nil {
+		return nil, fmt.Errorf("no checkpoint in experiment %q matched the selector", obj.Experiment.ShortID())
+	}
+	return checkpoint, nil
+}
+
 // loadCheckpoint returns a checkpoint given a prefix. If the prefix matches a
 // checkpoint, that is returned. If the prefix matches an experiment, it
 // returns the best checkpoint if a primary metric is defined in config,
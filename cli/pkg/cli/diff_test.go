@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRenderTableStructuredEmptyRunsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderTableStructured(&buf, nil, []string{"params.lr"}, "json"); err != nil {
+		t.Fatalf("renderTableStructured: %v", err)
+	}
+
+	var decoded struct {
+		Schema  string     `json:"schema"`
+		Columns []string   `json:"columns"`
+		Rows    []tableRow `json:"rows"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\noutput: %s", err, buf.String())
+	}
+	if decoded.Schema != tableSchema {
+		t.Errorf("schema = %q, want %q", decoded.Schema, tableSchema)
+	}
+	if len(decoded.Rows) != 0 {
+		t.Errorf("rows = %#v, want empty", decoded.Rows)
+	}
+}
+
+func TestRenderTableStructuredEmptyRunsNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderTableStructured(&buf, nil, []string{"params.lr"}, "ndjson"); err != nil {
+		t.Fatalf("renderTableStructured: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "" {
+		t.Errorf("ndjson output = %q, want zero lines for no runs", got)
+	}
+}
+
+func TestContains(t *testing.T) {
+	haystack := []string{"text", "json", "yaml"}
+	if !contains(haystack, "json") {
+		t.Error("contains(haystack, \"json\") = false, want true")
+	}
+	if contains(haystack, "ndjson") {
+		t.Error("contains(haystack, \"ndjson\") = true, want false")
+	}
+}
+
+func TestDiffFieldsParamsHaveNoDelta(t *testing.T) {
+	left := map[string]string{"learning_rate": "0.1"}
+	right := map[string]string{"learning_rate": "0.2"}
+	fields := diffFields(left, right, false)
+
+	fd, ok := fields["learning_rate"]
+	if !ok {
+		t.Fatalf("missing learning_rate in %#v", fields)
+	}
+	if fd.Delta != nil || fd.PctDelta != nil {
+		t.Errorf("params diffFields set Delta=%v PctDelta=%v, want both nil", fd.Delta, fd.PctDelta)
+	}
+	if !fd.Changed || fd.Left != "0.1" || fd.Right != "0.2" {
+		t.Errorf("diffFields(params) = %#v, want changed 0.1 -> 0.2", fd)
+	}
+}
+
+func TestDiffFieldsMetricsHaveDelta(t *testing.T) {
+	left := map[string]string{"loss": "0.5"}
+	right := map[string]string{"loss": "0.25"}
+	fields := diffFields(left, right, true)
+
+	fd, ok := fields["loss"]
+	if !ok {
+		t.Fatalf("missing loss in %#v", fields)
+	}
+	if fd.Delta == nil || *fd.Delta != -0.25 {
+		t.Errorf("diffFields(metrics).Delta = %v, want -0.25", fd.Delta)
+	}
+	if fd.PctDelta == nil || *fd.PctDelta != -50 {
+		t.Errorf("diffFields(metrics).PctDelta = %v, want -50", fd.PctDelta)
+	}
+}
+
+func TestDiffFieldsMetricsNonNumericNoDelta(t *testing.T) {
+	left := map[string]string{"status": "running"}
+	right := map[string]string{"status": "done"}
+	fields := diffFields(left, right, true)
+
+	fd := fields["status"]
+	if fd.Delta != nil || fd.PctDelta != nil {
+		t.Errorf("non-numeric metric got Delta=%v PctDelta=%v, want both nil", fd.Delta, fd.PctDelta)
+	}
+}
+
+func TestDiffFieldsUnchangedKeyStillPresent(t *testing.T) {
+	left := map[string]string{"optimizer": "adam"}
+	right := map[string]string{"optimizer": "adam"}
+	fields := diffFields(left, right, false)
+
+	fd, ok := fields["optimizer"]
+	if !ok {
+		t.Fatalf("missing optimizer in %#v", fields)
+	}
+	if fd.Changed {
+		t.Errorf("diffFields unchanged key marked Changed=true")
+	}
+	if fd.Left != "adam" || fd.Right != "adam" {
+		t.Errorf("diffFields unchanged key = %#v, want left=right=adam", fd)
+	}
+}
+
+func TestParseProjection(t *testing.T) {
+	if got := parseProjection(""); got != nil {
+		t.Errorf("parseProjection(\"\") = %#v, want nil", got)
+	}
+	got := parseProjection("params.learning_rate, metrics.loss ,labels.dataset")
+	want := []string{"params.learning_rate", "metrics.loss", "labels.dataset"}
+	if len(got) != len(want) {
+		t.Fatalf("parseProjection = %#v, want %#v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseProjection[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestUnionColumnsOrdersByNamespace(t *testing.T) {
+	runs := []*run{
+		{values: map[string]string{"metrics.loss": "0.1", "params.lr": "0.01", "labels.dataset": "cifar"}},
+		{values: map[string]string{"params.lr": "0.01"}},
+	}
+	got := unionColumns(runs)
+	want := []string{"params.lr", "metrics.loss", "labels.dataset"}
+	if len(got) != len(want) {
+		t.Fatalf("unionColumns = %#v, want %#v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("unionColumns[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCollapseConstant(t *testing.T) {
+	runs := []*run{
+		{values: map[string]string{"params.lr": "0.1", "params.optimizer": "adam"}},
+		{values: map[string]string{"params.lr": "0.2", "params.optimizer": "adam"}},
+	}
+	varying, constant := collapseConstant(runs, []string{"params.lr", "params.optimizer"})
+
+	if len(varying) != 1 || varying[0] != "params.lr" {
+		t.Errorf("varying = %#v, want [params.lr]", varying)
+	}
+	if v, ok := constant["params.optimizer"]; !ok || v != "adam" {
+		t.Errorf("constant = %#v, want params.optimizer=adam", constant)
+	}
+}
+
+func TestCollapseConstantUnsetColumn(t *testing.T) {
+	runs := []*run{
+		{values: map[string]string{}},
+		{values: map[string]string{"params.lr": "0.1"}},
+	}
+	varying, constant := collapseConstant(runs, []string{"params.lr"})
+	if len(constant) != 0 {
+		t.Errorf("constant = %#v, want empty since the column isn't set on every run", constant)
+	}
+	if len(varying) != 1 || varying[0] != "params.lr" {
+		t.Errorf("varying = %#v, want [params.lr]", varying)
+	}
+}
+
+func TestMapString(t *testing.T) {
+	left := map[string]string{"layers": "2", "foo": "bar"}
+	right := map[string]string{"layers": "4"}
+	got := mapString(left, right)
+
+	if len(got) != 2 {
+		t.Fatalf("mapString = %#v, want 2 keys", got)
+	}
+	if v := got["layers"]; v == nil || v[0] == nil || v[1] == nil || *v[0] != "2" || *v[1] != "4" {
+		t.Errorf("mapString[\"layers\"] = %#v, want [\"2\", \"4\"]", v)
+	}
+	if v := got["foo"]; v == nil || v[0] == nil || *v[0] != "bar" || v[1] != nil {
+		t.Errorf("mapString[\"foo\"] = %#v, want [\"bar\", nil]", v)
+	}
+}
+
+func TestSplitColumn(t *testing.T) {
+	ns, key := splitColumn("params.learning_rate")
+	if ns != "params" || key != "learning_rate" {
+		t.Errorf("splitColumn = (%q, %q), want (params, learning_rate)", ns, key)
+	}
+	ns, key = splitColumn("noNamespace")
+	if ns != "" || key != "noNamespace" {
+		t.Errorf("splitColumn(no dot) = (%q, %q), want (\"\", noNamespace)", ns, key)
+	}
+}
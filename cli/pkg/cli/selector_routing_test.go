@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"testing"
+
+	"replicate.ai/cli/pkg/project"
+)
+
+func TestParseSelectorRouting(t *testing.T) {
+	cases := []struct {
+		expr string
+		want interface{}
+	}{
+		{"", nil},
+		{"best", &project.BestSelector{}},
+		{"latest", &project.LatestSelector{}},
+		{"first", &project.FirstSelector{}},
+		{"step=1000", &project.AtStepSelector{Step: 1000}},
+		{"step = -5", &project.AtStepSelector{Step: -5}},
+		{"epoch=5", &project.AtEpochSelector{Epoch: 5}},
+		{"metrics.loss<0.1", &project.QuerySelector{Expr: "metrics.loss<0.1"}},
+	}
+	for _, c := range cases {
+		t.Run(c.expr, func(t *testing.T) {
+			got, err := parseSelector(c.expr)
+			if err != nil {
+				t.Fatalf("parseSelector(%q): %v", c.expr, err)
+			}
+			switch want := c.want.(type) {
+			case nil:
+				if got != nil {
+					t.Errorf("parseSelector(%q) = %#v, want nil", c.expr, got)
+				}
+			case *project.AtStepSelector:
+				sel, ok := got.(*project.AtStepSelector)
+				if !ok || sel.Step != want.Step {
+					t.Errorf("parseSelector(%q) = %#v, want %#v", c.expr, got, want)
+				}
+			case *project.AtEpochSelector:
+				sel, ok := got.(*project.AtEpochSelector)
+				if !ok || sel.Epoch != want.Epoch {
+					t.Errorf("parseSelector(%q) = %#v, want %#v", c.expr, got, want)
+				}
+			case *project.QuerySelector:
+				sel, ok := got.(*project.QuerySelector)
+				if !ok || sel.Expr != want.Expr {
+					t.Errorf("parseSelector(%q) = %#v, want %#v", c.expr, got, want)
+				}
+			case *project.BestSelector:
+				if _, ok := got.(*project.BestSelector); !ok {
+					t.Errorf("parseSelector(%q) = %#v, want %T", c.expr, got, want)
+				}
+			case *project.LatestSelector:
+				if _, ok := got.(*project.LatestSelector); !ok {
+					t.Errorf("parseSelector(%q) = %#v, want %T", c.expr, got, want)
+				}
+			case *project.FirstSelector:
+				if _, ok := got.(*project.FirstSelector); !ok {
+					t.Errorf("parseSelector(%q) = %#v, want %T", c.expr, got, want)
+				}
+			}
+		})
+	}
+}
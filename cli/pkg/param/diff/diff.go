@@ -0,0 +1,259 @@
+// Package diff implements a recursive structural diff over param.Value,
+// in the style of godebug's pretty-printer: line-oriented, indented output
+// with a "+"/"-" gutter per changed leaf, sharing common prefix lines where
+// nested params (lists, dicts, tensors-as-lists) are unchanged.
+package diff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"replicate.ai/cli/pkg/param"
+)
+
+// Op is the gutter of a DiffLine.
+type Op rune
+
+const (
+	Same Op = ' '
+	Add  Op = '+'
+	Del  Op = '-'
+)
+
+// DiffLine is one line of rendered diff output.
+type DiffLine struct {
+	Indent int
+	Op     Op
+	Text   string
+}
+
+// Options configures how values are compared.
+type Options struct {
+	// Tolerance is the maximum absolute difference between two numbers for
+	// them to be considered equal. Zero means exact match.
+	Tolerance float64
+}
+
+// Diff returns the line-oriented structural diff between a and b. Either may
+// be nil, meaning the param is not set on that side.
+func Diff(a, b *param.Value, opts Options) []DiffLine {
+	return diffValue(rawOf(a), rawOf(b), 0, opts)
+}
+
+func rawOf(v *param.Value) interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.Raw()
+}
+
+func diffValue(a, b interface{}, indent int, opts Options) []DiffLine {
+	if valuesEqual(a, b, opts) {
+		return renderLines(a, indent, Same)
+	}
+
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		return diffMaps(aMap, bMap, indent, opts)
+	}
+
+	aList, aIsList := a.([]interface{})
+	bList, bIsList := b.([]interface{})
+	if aIsList && bIsList {
+		return diffLists(aList, bList, indent, opts)
+	}
+
+	// Type mismatch or differing scalar: the whole subtree is removed and
+	// the new one added, no partial sharing is possible.
+	lines := []DiffLine{}
+	if a != nil {
+		lines = append(lines, renderLines(a, indent, Del)...)
+	}
+	if b != nil {
+		lines = append(lines, renderLines(b, indent, Add)...)
+	}
+	return lines
+}
+
+func diffMaps(a, b map[string]interface{}, indent int, opts Options) []DiffLine {
+	lines := []DiffLine{}
+	for _, k := range sortedKeys(a, b) {
+		av, aOk := a[k]
+		bv, bOk := b[k]
+		switch {
+		case aOk && bOk:
+			sub := diffValue(av, bv, indent+1, opts)
+			lines = append(lines, withKeyPrefix(k, sub)...)
+		case aOk:
+			sub := renderLines(av, indent+1, Del)
+			lines = append(lines, withKeyPrefix(k, sub)...)
+		case bOk:
+			sub := renderLines(bv, indent+1, Add)
+			lines = append(lines, withKeyPrefix(k, sub)...)
+		}
+	}
+	return lines
+}
+
+// withKeyPrefix prefixes the first line of a diffed value with "key:". No
+// brace lines are emitted, so a nested dict's first line reads like
+// `foo: bar: 1`, with its other entries on the following indented lines.
+func withKeyPrefix(key string, lines []DiffLine) []DiffLine {
+	if len(lines) == 0 {
+		return lines
+	}
+	out := make([]DiffLine, len(lines))
+	copy(out, lines)
+	out[0].Text = fmt.Sprintf("%s: %s", key, out[0].Text)
+	return out
+}
+
+// diffLists aligns a and b with an LCS so that appending or removing one
+// element doesn't render the whole list as changed.
+func diffLists(a, b []interface{}, indent int, opts Options) []DiffLine {
+	same := lcs(a, b, opts)
+
+	lines := []DiffLine{}
+	i, j := 0, 0
+	for _, pair := range same {
+		for i < pair[0] {
+			lines = append(lines, renderLines(a[i], indent+1, Del)...)
+			i++
+		}
+		for j < pair[1] {
+			lines = append(lines, renderLines(b[j], indent+1, Add)...)
+			j++
+		}
+		lines = append(lines, diffValue(a[pair[0]], b[pair[1]], indent+1, opts)...)
+		i++
+		j++
+	}
+	for i < len(a) {
+		lines = append(lines, renderLines(a[i], indent+1, Del)...)
+		i++
+	}
+	for j < len(b) {
+		lines = append(lines, renderLines(b[j], indent+1, Add)...)
+		j++
+	}
+	return lines
+}
+
+// lcs returns the indices of the longest common subsequence of elements in
+// a and b that are equal (within opts.Tolerance), as (i, j) pairs.
+func lcs(a, b []interface{}, opts Options) [][2]int {
+	n, m := len(a), len(b)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if valuesEqual(a[i], b[j], opts) {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	pairs := [][2]int{}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case valuesEqual(a[i], b[j], opts):
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+func valuesEqual(a, b interface{}, opts Options) bool {
+	if af, aOk := a.(float64); aOk {
+		if bf, bOk := b.(float64); bOk {
+			return math.Abs(af-bf) <= opts.Tolerance
+		}
+		return false
+	}
+
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if aIsMap || bIsMap {
+		if !aIsMap || !bIsMap || len(aMap) != len(bMap) {
+			return false
+		}
+		for k, av := range aMap {
+			bv, ok := bMap[k]
+			if !ok || !valuesEqual(av, bv, opts) {
+				return false
+			}
+		}
+		return true
+	}
+
+	aList, aIsList := a.([]interface{})
+	bList, bIsList := b.([]interface{})
+	if aIsList || bIsList {
+		if !aIsList || !bIsList || len(aList) != len(bList) {
+			return false
+		}
+		for i := range aList {
+			if !valuesEqual(aList[i], bList[i], opts) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return a == b
+}
+
+// renderLines flattens a value into DiffLines, recursing into maps/lists so
+// that unchanged nested structures still render in full.
+func renderLines(v interface{}, indent int, op Op) []DiffLine {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		lines := []DiffLine{}
+		for _, k := range sortedKeys(val, nil) {
+			lines = append(lines, withKeyPrefix(k, renderLines(val[k], indent+1, op))...)
+		}
+		return lines
+	case []interface{}:
+		lines := []DiffLine{}
+		for _, e := range val {
+			lines = append(lines, renderLines(e, indent+1, op)...)
+		}
+		return lines
+	default:
+		return []DiffLine{{Indent: indent, Op: op, Text: fmt.Sprintf("%v", val)}}
+	}
+}
+
+func sortedKeys(a, b map[string]interface{}) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for k := range a {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for k := range b {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
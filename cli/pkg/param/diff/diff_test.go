@@ -0,0 +1,118 @@
+package diff
+
+import "testing"
+
+func TestValuesEqualTolerance(t *testing.T) {
+	cases := []struct {
+		name      string
+		a, b      interface{}
+		tolerance float64
+		want      bool
+	}{
+		{"exact match", 1.0, 1.0, 0, true},
+		{"within tolerance", 1.0, 1.0000001, 1e-3, true},
+		{"outside tolerance", 1.0, 1.0000001, 0, false},
+		{"type mismatch", 1.0, "1", 0, false},
+		{"equal strings", "adam", "adam", 0, true},
+		{"different strings", "adam", "sgd", 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := valuesEqual(c.a, c.b, Options{Tolerance: c.tolerance})
+			if got != c.want {
+				t.Errorf("valuesEqual(%v, %v, tolerance=%v) = %v, want %v", c.a, c.b, c.tolerance, got, c.want)
+			}
+		})
+	}
+}
+
+func countOps(lines []DiffLine, op Op) int {
+	n := 0
+	for _, l := range lines {
+		if l.Op == op {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDiffListAppend(t *testing.T) {
+	a := []interface{}{1.0, 2.0, 3.0}
+	b := []interface{}{1.0, 2.0, 3.0, 4.0}
+	lines := diffValue(a, b, 0, Options{})
+
+	if got, want := countOps(lines, Same), 3; got != want {
+		t.Errorf("got %d same lines, want %d: %+v", got, want, lines)
+	}
+	if got, want := countOps(lines, Add), 1; got != want {
+		t.Errorf("got %d add lines, want %d: %+v", got, want, lines)
+	}
+	if got, want := countOps(lines, Del), 0; got != want {
+		t.Errorf("got %d del lines, want %d: %+v", got, want, lines)
+	}
+}
+
+func TestDiffListRemove(t *testing.T) {
+	a := []interface{}{1.0, 2.0, 3.0, 4.0}
+	b := []interface{}{1.0, 2.0, 3.0}
+	lines := diffValue(a, b, 0, Options{})
+
+	if got, want := countOps(lines, Same), 3; got != want {
+		t.Errorf("got %d same lines, want %d: %+v", got, want, lines)
+	}
+	if got, want := countOps(lines, Del), 1; got != want {
+		t.Errorf("got %d del lines, want %d: %+v", got, want, lines)
+	}
+	if got, want := countOps(lines, Add), 0; got != want {
+		t.Errorf("got %d add lines, want %d: %+v", got, want, lines)
+	}
+}
+
+func TestDiffListInsertMiddle(t *testing.T) {
+	a := []interface{}{1.0, 2.0, 3.0}
+	b := []interface{}{1.0, 5.0, 2.0, 3.0}
+	lines := diffValue(a, b, 0, Options{})
+
+	if got, want := countOps(lines, Same), 3; got != want {
+		t.Errorf("got %d same lines, want %d: %+v", got, want, lines)
+	}
+	if got, want := countOps(lines, Add), 1; got != want {
+		t.Errorf("got %d add lines, want %d: %+v", got, want, lines)
+	}
+}
+
+func TestDiffListWithinTolerance(t *testing.T) {
+	a := []interface{}{1.0, 2.0}
+	b := []interface{}{1.0000001, 2.0}
+	lines := diffValue(a, b, 0, Options{Tolerance: 1e-3})
+
+	if got, want := countOps(lines, Same), 2; got != want {
+		t.Errorf("got %d same lines, want %d: %+v", got, want, lines)
+	}
+	if len(lines) != 2 {
+		t.Errorf("expected no add/del lines within tolerance, got %+v", lines)
+	}
+}
+
+func TestDiffScalarTypeMismatch(t *testing.T) {
+	lines := diffValue(1.0, "1", 0, Options{})
+	if got, want := countOps(lines, Del), 1; got != want {
+		t.Errorf("got %d del lines, want %d: %+v", got, want, lines)
+	}
+	if got, want := countOps(lines, Add), 1; got != want {
+		t.Errorf("got %d add lines, want %d: %+v", got, want, lines)
+	}
+}
+
+func TestDiffMapChangedKeyOnly(t *testing.T) {
+	a := map[string]interface{}{"lr": 0.1, "layers": 2.0}
+	b := map[string]interface{}{"lr": 0.2, "layers": 2.0}
+	lines := diffValue(a, b, 0, Options{})
+
+	if got, want := countOps(lines, Same), 1; got != want {
+		t.Errorf("got %d same lines, want %d: %+v", got, want, lines)
+	}
+	if got, want := countOps(lines, Del)+countOps(lines, Add), 2; got != want {
+		t.Errorf("got %d changed lines, want %d: %+v", got, want, lines)
+	}
+}
@@ -0,0 +1,188 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"replicate.ai/cli/pkg/query"
+)
+
+// checkpointProvider is the subset of *Project that selectors need in order
+// to pick a checkpoint. It exists so selectors can be tested against a fake
+// without standing up a real Project.
+type checkpointProvider interface {
+	ExperimentCheckpoints(experimentID string) ([]*Checkpoint, error)
+	ExperimentBestCheckpoint(experimentID string) (*Checkpoint, error)
+	ExperimentLatestCheckpoint(experimentID string) (*Checkpoint, error)
+}
+
+// CheckpointSelector picks a single checkpoint out of an experiment, e.g. the
+// best one by primary metric, the latest, or one matching an ad-hoc query.
+type CheckpointSelector interface {
+	SelectCheckpoint(proj checkpointProvider, experimentID string) (*Checkpoint, error)
+}
+
+// BestSelector picks the best checkpoint by primary metric, falling back to
+// the latest checkpoint if no primary metric is defined. This is the
+// existing `replicate diff` default.
+type BestSelector struct{}
+
+func (s *BestSelector) SelectCheckpoint(proj checkpointProvider, experimentID string) (*Checkpoint, error) {
+	checkpoint, err := proj.ExperimentBestCheckpoint(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil {
+		return checkpoint, nil
+	}
+	return proj.ExperimentLatestCheckpoint(experimentID)
+}
+
+// LatestSelector picks the most recently created checkpoint.
+type LatestSelector struct{}
+
+func (s *LatestSelector) SelectCheckpoint(proj checkpointProvider, experimentID string) (*Checkpoint, error) {
+	return proj.ExperimentLatestCheckpoint(experimentID)
+}
+
+// FirstSelector picks the checkpoint with the lowest step.
+type FirstSelector struct{}
+
+func (s *FirstSelector) SelectCheckpoint(proj checkpointProvider, experimentID string) (*Checkpoint, error) {
+	checkpoints, err := proj.ExperimentCheckpoints(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	return firstByStep(checkpoints), nil
+}
+
+func firstByStep(checkpoints []*Checkpoint) *Checkpoint {
+	var first *Checkpoint
+	for _, c := range checkpoints {
+		if first == nil || c.Step < first.Step {
+			first = c
+		}
+	}
+	return first
+}
+
+// AtStepSelector picks the checkpoint at a particular step.
+type AtStepSelector struct {
+	Step int
+}
+
+func (s *AtStepSelector) SelectCheckpoint(proj checkpointProvider, experimentID string) (*Checkpoint, error) {
+	checkpoints, err := proj.ExperimentCheckpoints(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range checkpoints {
+		if c.Step == s.Step {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// AtEpochSelector picks the checkpoint at a particular epoch.
+type AtEpochSelector struct {
+	Epoch int
+}
+
+func (s *AtEpochSelector) SelectCheckpoint(proj checkpointProvider, experimentID string) (*Checkpoint, error) {
+	checkpoints, err := proj.ExperimentCheckpoints(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range checkpoints {
+		if c.Epoch == s.Epoch {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+var aggregateSelectorRegexp = regexp.MustCompile(`^(argmin|argmax)\(\s*(.+?)\s*\)$`)
+
+// QuerySelector picks a checkpoint using a small query language over
+// metrics/step/epoch: `metrics.<k> op <num|str>`, `step op N`, `epoch op N`,
+// combined with AND/OR/NOT, or an aggregate `argmin(<field>)`/`argmax(<field>)`.
+// Of the checkpoints matching a boolean expression, the one at the highest
+// step is picked.
+type QuerySelector struct {
+	Expr string
+}
+
+func (s *QuerySelector) SelectCheckpoint(proj checkpointProvider, experimentID string) (*Checkpoint, error) {
+	checkpoints, err := proj.ExperimentCheckpoints(experimentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if m := aggregateSelectorRegexp.FindStringSubmatch(strings.TrimSpace(s.Expr)); m != nil {
+		return selectAggregate(checkpoints, m[1], m[2])
+	}
+
+	expr, err := query.Parse(s.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", s.Expr, err)
+	}
+
+	var best *Checkpoint
+	for _, c := range checkpoints {
+		ok, err := expr.Eval(checkpointRow{c})
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", s.Expr, err)
+		}
+		if ok && (best == nil || c.Step > best.Step) {
+			best = c
+		}
+	}
+	return best, nil
+}
+
+func selectAggregate(checkpoints []*Checkpoint, fn, field string) (*Checkpoint, error) {
+	var best *Checkpoint
+	var bestVal float64
+	for _, c := range checkpoints {
+		v, ok := checkpointRow{c}.Lookup(field)
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			continue
+		}
+		if best == nil ||
+			(fn == "argmin" && f < bestVal) ||
+			(fn == "argmax" && f > bestVal) {
+			best = c
+			bestVal = f
+		}
+	}
+	return best, nil
+}
+
+// checkpointRow adapts a Checkpoint to query.Row, so the selector mini
+// language can refer to "metrics.<k>", "step" and "epoch".
+type checkpointRow struct {
+	checkpoint *Checkpoint
+}
+
+func (r checkpointRow) Lookup(field string) (string, bool) {
+	switch field {
+	case "step":
+		return strconv.Itoa(r.checkpoint.Step), true
+	case "epoch":
+		return strconv.Itoa(r.checkpoint.Epoch), true
+	}
+	if strings.HasPrefix(field, "metrics.") {
+		key := strings.TrimPrefix(field, "metrics.")
+		if v, ok := r.checkpoint.Metrics[key]; ok {
+			return v.String(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,296 @@
+package project
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeCheckpointProvider is a checkpointProvider backed by a fixed
+// in-memory checkpoint list, so SelectCheckpoint can be exercised without a
+// real Project.
+type fakeCheckpointProvider struct {
+	checkpoints []*Checkpoint
+	best        *Checkpoint
+	bestErr     error
+	latest      *Checkpoint
+	latestErr   error
+}
+
+func (f *fakeCheckpointProvider) ExperimentCheckpoints(experimentID string) ([]*Checkpoint, error) {
+	return f.checkpoints, nil
+}
+
+func (f *fakeCheckpointProvider) ExperimentBestCheckpoint(experimentID string) (*Checkpoint, error) {
+	return f.best, f.bestErr
+}
+
+func (f *fakeCheckpointProvider) ExperimentLatestCheckpoint(experimentID string) (*Checkpoint, error) {
+	return f.latest, f.latestErr
+}
+
+func TestFirstByStep(t *testing.T) {
+	checkpoints := []*Checkpoint{
+		{Step: 300},
+		{Step: 100},
+		{Step: 200},
+	}
+	got := firstByStep(checkpoints)
+	if got.Step != 100 {
+		t.Errorf("firstByStep = step %d, want 100", got.Step)
+	}
+}
+
+func TestFirstByStepEmpty(t *testing.T) {
+	if got := firstByStep(nil); got != nil {
+		t.Errorf("firstByStep(nil) = %v, want nil", got)
+	}
+}
+
+func TestCheckpointRowLookup(t *testing.T) {
+	c := &Checkpoint{Step: 42, Epoch: 3}
+	row := checkpointRow{c}
+
+	cases := []struct {
+		field string
+		want  string
+		ok    bool
+	}{
+		{"step", "42", true},
+		{"epoch", "3", true},
+		{"metrics.missing", "", false},
+	}
+	for _, c := range cases {
+		got, ok := row.Lookup(c.field)
+		if got != c.want || ok != c.ok {
+			t.Errorf("Lookup(%q) = (%q, %v), want (%q, %v)", c.field, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestSelectAggregate(t *testing.T) {
+	checkpoints := []*Checkpoint{
+		{Step: 1},
+		{Step: 2},
+		{Step: 3},
+	}
+
+	min, err := selectAggregate(checkpoints, "argmin", "step")
+	if err != nil {
+		t.Fatalf("selectAggregate(argmin): %v", err)
+	}
+	if min == nil || min.Step != 1 {
+		t.Errorf("argmin(step) = %v, want step 1", min)
+	}
+
+	max, err := selectAggregate(checkpoints, "argmax", "step")
+	if err != nil {
+		t.Fatalf("selectAggregate(argmax): %v", err)
+	}
+	if max == nil || max.Step != 3 {
+		t.Errorf("argmax(step) = %v, want step 3", max)
+	}
+}
+
+func TestSelectAggregateTieBreaksFirst(t *testing.T) {
+	checkpoints := []*Checkpoint{
+		{Step: 1, Epoch: 5},
+		{Step: 2, Epoch: 5},
+	}
+
+	got, err := selectAggregate(checkpoints, "argmax", "epoch")
+	if err != nil {
+		t.Fatalf("selectAggregate(argmax): %v", err)
+	}
+	if got == nil || got.Step != 1 {
+		t.Errorf("argmax(epoch) tie should keep the first seen, got %v", got)
+	}
+}
+
+func TestSelectAggregateEmpty(t *testing.T) {
+	got, err := selectAggregate(nil, "argmin", "step")
+	if err != nil {
+		t.Fatalf("selectAggregate(nil): %v", err)
+	}
+	if got != nil {
+		t.Errorf("selectAggregate(nil) = %v, want nil", got)
+	}
+}
+
+func TestBestSelectorSelectCheckpoint(t *testing.T) {
+	best := &Checkpoint{Step: 200}
+	proj := &fakeCheckpointProvider{best: best}
+	got, err := (&BestSelector{}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got != best {
+		t.Errorf("SelectCheckpoint = %v, want %v", got, best)
+	}
+}
+
+func TestBestSelectorFallsBackToLatest(t *testing.T) {
+	latest := &Checkpoint{Step: 300}
+	proj := &fakeCheckpointProvider{best: nil, latest: latest}
+	got, err := (&BestSelector{}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got != latest {
+		t.Errorf("SelectCheckpoint = %v, want fallback %v", got, latest)
+	}
+}
+
+func TestBestSelectorPropagatesError(t *testing.T) {
+	proj := &fakeCheckpointProvider{bestErr: errors.New("boom")}
+	if _, err := (&BestSelector{}).SelectCheckpoint(proj, "exp"); err == nil {
+		t.Error("expected error from ExperimentBestCheckpoint to propagate")
+	}
+}
+
+func TestLatestSelectorSelectCheckpoint(t *testing.T) {
+	latest := &Checkpoint{Step: 42}
+	proj := &fakeCheckpointProvider{latest: latest}
+	got, err := (&LatestSelector{}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got != latest {
+		t.Errorf("SelectCheckpoint = %v, want %v", got, latest)
+	}
+}
+
+func TestFirstSelectorSelectCheckpoint(t *testing.T) {
+	proj := &fakeCheckpointProvider{checkpoints: []*Checkpoint{
+		{Step: 300}, {Step: 100}, {Step: 200},
+	}}
+	got, err := (&FirstSelector{}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got == nil || got.Step != 100 {
+		t.Errorf("SelectCheckpoint = %v, want step 100", got)
+	}
+}
+
+func TestAtStepSelectorSelectCheckpoint(t *testing.T) {
+	proj := &fakeCheckpointProvider{checkpoints: []*Checkpoint{
+		{Step: 100}, {Step: 200}, {Step: 300},
+	}}
+	got, err := (&AtStepSelector{Step: 200}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got == nil || got.Step != 200 {
+		t.Errorf("SelectCheckpoint = %v, want step 200", got)
+	}
+}
+
+func TestAtStepSelectorNoMatch(t *testing.T) {
+	proj := &fakeCheckpointProvider{checkpoints: []*Checkpoint{
+		{Step: 100}, {Step: 300},
+	}}
+	got, err := (&AtStepSelector{Step: 200}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got != nil {
+		t.Errorf("SelectCheckpoint = %v, want nil for no match", got)
+	}
+}
+
+func TestAtEpochSelectorSelectCheckpoint(t *testing.T) {
+	proj := &fakeCheckpointProvider{checkpoints: []*Checkpoint{
+		{Epoch: 1}, {Epoch: 5},
+	}}
+	got, err := (&AtEpochSelector{Epoch: 5}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got == nil || got.Epoch != 5 {
+		t.Errorf("SelectCheckpoint = %v, want epoch 5", got)
+	}
+}
+
+func TestAtEpochSelectorNoMatch(t *testing.T) {
+	proj := &fakeCheckpointProvider{checkpoints: []*Checkpoint{
+		{Epoch: 1}, {Epoch: 2},
+	}}
+	got, err := (&AtEpochSelector{Epoch: 5}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got != nil {
+		t.Errorf("SelectCheckpoint = %v, want nil for no match", got)
+	}
+}
+
+func TestQuerySelectorSelectCheckpoint(t *testing.T) {
+	proj := &fakeCheckpointProvider{checkpoints: []*Checkpoint{
+		{Step: 100, Epoch: 1},
+		{Step: 200, Epoch: 2},
+	}}
+	got, err := (&QuerySelector{Expr: "epoch<2"}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got == nil || got.Step != 100 {
+		t.Errorf("SelectCheckpoint = %v, want step 100", got)
+	}
+}
+
+func TestQuerySelectorNoMatch(t *testing.T) {
+	proj := &fakeCheckpointProvider{checkpoints: []*Checkpoint{
+		{Step: 100, Epoch: 1},
+	}}
+	got, err := (&QuerySelector{Expr: "epoch>5"}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got != nil {
+		t.Errorf("SelectCheckpoint = %v, want nil for no match", got)
+	}
+}
+
+func TestQuerySelectorAggregate(t *testing.T) {
+	proj := &fakeCheckpointProvider{checkpoints: []*Checkpoint{
+		{Step: 100, Epoch: 5},
+		{Step: 200, Epoch: 1},
+	}}
+	got, err := (&QuerySelector{Expr: "argmin(epoch)"}).SelectCheckpoint(proj, "exp")
+	if err != nil {
+		t.Fatalf("SelectCheckpoint: %v", err)
+	}
+	if got == nil || got.Step != 200 {
+		t.Errorf("SelectCheckpoint = %v, want step 200", got)
+	}
+}
+
+func TestAggregateSelectorRegexp(t *testing.T) {
+	cases := []struct {
+		expr    string
+		matches bool
+		fn      string
+		field   string
+	}{
+		{"argmin(metrics.loss)", true, "argmin", "metrics.loss"},
+		{"argmax( step )", true, "argmax", "step"},
+		{"best", false, "", ""},
+		{"step=10", false, "", ""},
+	}
+	for _, c := range cases {
+		m := aggregateSelectorRegexp.FindStringSubmatch(c.expr)
+		if c.matches && m == nil {
+			t.Errorf("expected %q to match aggregateSelectorRegexp", c.expr)
+			continue
+		}
+		if !c.matches {
+			if m != nil {
+				t.Errorf("expected %q not to match aggregateSelectorRegexp", c.expr)
+			}
+			continue
+		}
+		if m[1] != c.fn || m[2] != c.field {
+			t.Errorf("aggregateSelectorRegexp(%q) = (%q, %q), want (%q, %q)", c.expr, m[1], m[2], c.fn, c.field)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses a filter expression, e.g. `metrics.loss<0.1 AND params.optimizer=adam`.
+func Parse(input string) (Expr, error) {
+	p := &parser{tokens: tokenize(input)}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q in filter expression", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "AND") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if strings.EqualFold(p.peek(), "NOT") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Expr: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	if p.peek() == "(" {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("expected closing paren in filter expression")
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	field := p.next()
+	if field == "" {
+		return nil, fmt.Errorf("expected field in filter expression")
+	}
+	op := p.next()
+	value := p.next()
+	if value == "" {
+		return nil, fmt.Errorf("expected value after %q%s", field, op)
+	}
+	switch op {
+	case "=":
+		return &Eq{Field: field, Value: value}, nil
+	case "!=":
+		return &Ne{Field: field, Value: value}, nil
+	case "<":
+		return &Lt{Field: field, Value: value}, nil
+	case ">":
+		return &Gt{Field: field, Value: value}, nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q in filter expression", op)
+	}
+}
+
+// tokenize splits a filter expression into fields, operators, values,
+// parens and AND/OR/NOT keywords.
+func tokenize(input string) []string {
+	tokens := []string{}
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			flush()
+		case ch == '(' || ch == ')':
+			flush()
+			tokens = append(tokens, string(ch))
+		case ch == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			flush()
+			tokens = append(tokens, "!=")
+			i++
+		case ch == '=' || ch == '<' || ch == '>':
+			flush()
+			tokens = append(tokens, string(ch))
+		default:
+			cur.WriteRune(ch)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// bothFloats parses a and b as float64s, returning ok=false if either fails.
+func bothFloats(a, b string) (float64, float64, bool) {
+	af, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	bf, err := strconv.ParseFloat(b, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return af, bf, true
+}
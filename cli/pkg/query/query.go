@@ -0,0 +1,141 @@
+// Package query implements a small expression language for filtering and
+// selecting over checkpoint/experiment data (params, metrics, labels).
+//
+// The grammar is:
+//
+//   expr       := orExpr
+//   orExpr     := andExpr ( "OR" andExpr )*
+//   andExpr    := unary ( "AND" unary )*
+//   unary      := "NOT" unary | primary
+//   primary    := "(" expr ")" | comparison
+//   comparison := field ("=" | "!=" | "<" | ">") value
+//
+// e.g. `metrics.loss<0.1 AND params.optimizer=adam`.
+package query
+
+import "fmt"
+
+// Row is looked up by field path (e.g. "metrics.loss", "params.optimizer")
+// when evaluating an Expr against it.
+type Row interface {
+	Lookup(field string) (value string, ok bool)
+}
+
+// Expr is a node in a parsed filter expression.
+type Expr interface {
+	Eval(row Row) (bool, error)
+}
+
+// Eq is true if the field is set and equal to Value.
+type Eq struct {
+	Field string
+	Value string
+}
+
+func (e *Eq) Eval(row Row) (bool, error) {
+	v, ok := row.Lookup(e.Field)
+	if !ok {
+		return false, nil
+	}
+	if lf, rf, ok := bothFloats(v, e.Value); ok {
+		return lf == rf, nil
+	}
+	return v == e.Value, nil
+}
+
+// Ne is true if the field is unset or not equal to Value.
+type Ne struct {
+	Field string
+	Value string
+}
+
+func (e *Ne) Eval(row Row) (bool, error) {
+	eq, err := (&Eq{e.Field, e.Value}).Eval(row)
+	if err != nil {
+		return false, err
+	}
+	return !eq, nil
+}
+
+// Lt is true if the field parses as a number less than Value.
+type Lt struct {
+	Field string
+	Value string
+}
+
+func (e *Lt) Eval(row Row) (bool, error) {
+	v, ok := row.Lookup(e.Field)
+	if !ok {
+		return false, nil
+	}
+	lf, rf, ok := bothFloats(v, e.Value)
+	if !ok {
+		return false, fmt.Errorf("cannot compare %q < %q: not numeric", v, e.Value)
+	}
+	return lf < rf, nil
+}
+
+// Gt is true if the field parses as a number greater than Value.
+type Gt struct {
+	Field string
+	Value string
+}
+
+func (e *Gt) Eval(row Row) (bool, error) {
+	v, ok := row.Lookup(e.Field)
+	if !ok {
+		return false, nil
+	}
+	lf, rf, ok := bothFloats(v, e.Value)
+	if !ok {
+		return false, fmt.Errorf("cannot compare %q > %q: not numeric", v, e.Value)
+	}
+	return lf > rf, nil
+}
+
+// And is true if both Left and Right are true.
+type And struct {
+	Left  Expr
+	Right Expr
+}
+
+func (e *And) Eval(row Row) (bool, error) {
+	l, err := e.Left.Eval(row)
+	if err != nil {
+		return false, err
+	}
+	if !l {
+		return false, nil
+	}
+	return e.Right.Eval(row)
+}
+
+// Or is true if either Left or Right is true.
+type Or struct {
+	Left  Expr
+	Right Expr
+}
+
+func (e *Or) Eval(row Row) (bool, error) {
+	l, err := e.Left.Eval(row)
+	if err != nil {
+		return false, err
+	}
+	if l {
+		return true, nil
+	}
+	return e.Right.Eval(row)
+}
+
+// Not negates Expr.
+type Not struct {
+	Expr Expr
+}
+
+func (e *Not) Eval(row Row) (bool, error) {
+	v, err := e.Expr.Eval(row)
+	if err != nil {
+		return false, err
+	}
+	return !v, nil
+}
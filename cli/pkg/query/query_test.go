@@ -0,0 +1,76 @@
+package query
+
+import "testing"
+
+type fakeRow map[string]string
+
+func (r fakeRow) Lookup(field string) (string, bool) {
+	v, ok := r[field]
+	return v, ok
+}
+
+func TestEvalTruthTable(t *testing.T) {
+	row := fakeRow{"metrics.loss": "0.05", "params.optimizer": "adam"}
+
+	cases := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{"eq match", "params.optimizer=adam", true},
+		{"eq mismatch", "params.optimizer=sgd", false},
+		{"ne", "params.optimizer!=sgd", true},
+		{"lt true", "metrics.loss<0.1", true},
+		{"lt false", "metrics.loss<0.01", false},
+		{"gt true", "metrics.loss>0.01", true},
+		{"and true", "metrics.loss<0.1 AND params.optimizer=adam", true},
+		{"and false", "metrics.loss<0.1 AND params.optimizer=sgd", false},
+		{"or true", "params.optimizer=sgd OR metrics.loss<0.1", true},
+		{"not", "NOT params.optimizer=sgd", true},
+		{"parens", "(metrics.loss<0.1 AND params.optimizer=adam) OR params.optimizer=sgd", true},
+		{"missing field", "metrics.accuracy=1", false},
+		{"case-insensitive keywords", "metrics.loss<0.1 and params.optimizer=adam", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := Parse(c.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", c.expr, err)
+			}
+			got, err := expr.Eval(row)
+			if err != nil {
+				t.Fatalf("Eval(%q): %v", c.expr, err)
+			}
+			if got != c.want {
+				t.Errorf("Eval(%q) = %v, want %v", c.expr, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"metrics.loss<",
+		"(metrics.loss<0.1",
+		"metrics.loss<0.1)",
+		"metrics.loss??0.1",
+	}
+	for _, expr := range cases {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): expected error, got nil", expr)
+		}
+	}
+}
+
+func TestLtGtRequireNumeric(t *testing.T) {
+	row := fakeRow{"params.optimizer": "adam"}
+	expr, err := Parse("params.optimizer<adam")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := expr.Eval(row); err == nil {
+		t.Error("expected error comparing non-numeric values with <")
+	}
+}